@@ -0,0 +1,160 @@
+package akeyless
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/akeylesslabs/akeyless-go/v2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceAuthMethodOidc() *schema.Resource {
+	return &schema.Resource{
+		Description: "OIDC Auth Method data source",
+		Read:        dataSourceAuthMethodOidcRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Auth Method name",
+			},
+			"access_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Auth Method access ID",
+			},
+			"access_expires": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Access expiration date in Unix timestamp",
+			},
+			"bound_ips": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Description: "A CIDR whitelist with the IPs that the access is restricted to",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"force_sub_claims": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "enforce role-association must include sub claims",
+			},
+			"unique_identifier": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "A unique identifier (ID) value configured for this authentication method",
+			},
+			"client_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Client ID",
+			},
+			"issuer": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Issuer URL",
+			},
+			"required_scopes": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Description: "A list of required scopes that the oidc client should support",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"required_scopes_prefix": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "A prefix to add to all required scopes",
+			},
+			"allowed_redirect_uri": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Description: "Allowed redirect URIs after the authentication",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceAuthMethodOidcRead(d *schema.ResourceData, m interface{}) error {
+	provider := m.(providerMeta)
+	client := *provider.client
+
+	var apiErr akeyless.GenericOpenAPIError
+	ctx := context.Background()
+	token, err := provider.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	name := d.Get("name").(string)
+
+	body := akeyless.GetAuthMethod{
+		Name:  name,
+		Token: &token,
+	}
+
+	rOut, _, err := client.GetAuthMethod(ctx).Body(body).Execute()
+	if err != nil {
+		if errors.As(err, &apiErr) {
+			return fmt.Errorf("can't get value: %v", string(apiErr.Body()))
+		}
+		return fmt.Errorf("can't get value: %v", err)
+	}
+
+	if rOut.AuthMethodAccessId != nil {
+		if err := d.Set("access_id", *rOut.AuthMethodAccessId); err != nil {
+			return err
+		}
+	}
+	if rOut.AccessInfo.AccessExpires != nil {
+		if err := d.Set("access_expires", *rOut.AccessInfo.AccessExpires); err != nil {
+			return err
+		}
+	}
+	if rOut.AccessInfo.ForceSubClaims != nil {
+		if err := d.Set("force_sub_claims", *rOut.AccessInfo.ForceSubClaims); err != nil {
+			return err
+		}
+	}
+	if rOut.AccessInfo.CidrWhitelist != nil && *rOut.AccessInfo.CidrWhitelist != "" {
+		if err := d.Set("bound_ips", strings.Split(*rOut.AccessInfo.CidrWhitelist, ",")); err != nil {
+			return err
+		}
+	}
+	if rOut.AccessInfo.OidcAccessRules.UniqueIdentifier != nil {
+		if err := d.Set("unique_identifier", *rOut.AccessInfo.OidcAccessRules.UniqueIdentifier); err != nil {
+			return err
+		}
+	}
+	if rOut.AccessInfo.OidcAccessRules.ClientId != nil {
+		if err := d.Set("client_id", *rOut.AccessInfo.OidcAccessRules.ClientId); err != nil {
+			return err
+		}
+	}
+	if rOut.AccessInfo.OidcAccessRules.Issuer != nil {
+		if err := d.Set("issuer", *rOut.AccessInfo.OidcAccessRules.Issuer); err != nil {
+			return err
+		}
+	}
+	if rOut.AccessInfo.OidcAccessRules.RequiredScopes != nil {
+		if err := d.Set("required_scopes", *rOut.AccessInfo.OidcAccessRules.RequiredScopes); err != nil {
+			return err
+		}
+	}
+	if rOut.AccessInfo.OidcAccessRules.RequiredScopesPrefix != nil {
+		if err := d.Set("required_scopes_prefix", *rOut.AccessInfo.OidcAccessRules.RequiredScopesPrefix); err != nil {
+			return err
+		}
+	}
+	if rOut.AccessInfo.OidcAccessRules.AllowedRedirectURIs != nil {
+		if err := d.Set("allowed_redirect_uri", *rOut.AccessInfo.OidcAccessRules.AllowedRedirectURIs); err != nil {
+			return err
+		}
+	}
+
+	d.SetId(name)
+
+	return nil
+}
@@ -4,121 +4,310 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/akeylesslabs/akeyless-go-cloud-id/cloudprovider/aws"
-	"github.com/akeylesslabs/akeyless-go-cloud-id/cloudprovider/azure"
+	azureCloudId "github.com/akeylesslabs/akeyless-go-cloud-id/cloudprovider/azure"
 	"github.com/akeylesslabs/akeyless-go/v2"
 	"github.com/akeylesslabs/terraform-provider-akeyless/akeyless/common"
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/defaults"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
 )
 
 // default: public API Gateway
 const publicApi = "https://api.akeyless.io"
 
-var apiKeyLogin []interface{}
-var emailLogin []interface{}
-var awsIAMLogin []interface{}
-var azureADLogin []interface{}
+// loginConfig holds the (at most one) configured login block, resolved once from
+// *schema.ResourceData by inputValidation. It is threaded explicitly through setAuthBody
+// instead of living in package-level vars, so that two providers configured in the same
+// process - or a background token refresh racing a fresh configure - can't clobber each
+// other's login settings.
+type loginConfig struct {
+	apiKeyLogin  []interface{}
+	emailLogin   []interface{}
+	awsIAMLogin  []interface{}
+	azureADLogin []interface{}
+}
 
-// Provider returns Akeyless Terraform provider
-func Provider() *schema.Provider {
-	return &schema.Provider{
-		Schema: map[string]*schema.Schema{
-			"api_gateway_address": {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Default:     publicApi,
-				Description: "Origin URL of the API Gateway server. This is a URL with a scheme, a hostname and a port.",
+// loginSchemas returns the four login blocks shared by the provider and the
+// akeyless remote-state backend, so both authenticate the same way.
+func loginSchemas() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"api_key_login": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "A configuration block, described below, that attempts to authenticate using API-Key.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"access_id": {
+						Type:        schema.TypeString,
+						Required:    true,
+						DefaultFunc: schema.EnvDefaultFunc("AKEYLESS_ACCESS_ID", nil),
+					},
+					"access_key": {
+						Type:        schema.TypeString,
+						Required:    true,
+						Sensitive:   true,
+						DefaultFunc: schema.EnvDefaultFunc("AKEYLESS_ACCESS_KEY", nil),
+					},
+				},
 			},
-			"api_key_login": {
-				Type:        schema.TypeList,
-				Optional:    true,
-				Description: "A configuration block, described below, that attempts to authenticate using API-Key.",
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"access_id": {
-							Type:        schema.TypeString,
-							Required:    true,
-							DefaultFunc: schema.EnvDefaultFunc("AKEYLESS_ACCESS_ID", nil),
-						},
-						"access_key": {
-							Type:        schema.TypeString,
-							Required:    true,
-							Sensitive:   true,
-							DefaultFunc: schema.EnvDefaultFunc("AKEYLESS_ACCESS_KEY", nil),
+		},
+		"aws_iam_login": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "A configuration block, described below, that attempts to authenticate using AWS-IAM authentication credentials. Known limitation: the resolved credentials are signed by briefly exporting them into this process's AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY/AWS_REGION environment variables (see getCloudIdWithCreds), because the underlying cloud-id library only reads credentials from the environment. Concurrent use of this provider is serialized against itself, but another AWS SDK client running in the same Terraform process (a provider or plugin outside this package) could observe the wrong identity's credentials for the moment they're exported.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"access_id": {
+						Type:     schema.TypeString,
+						Required: true,
+					},
+					"access_key": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						Sensitive:   true,
+						DefaultFunc: schema.EnvDefaultFunc("AWS_ACCESS_KEY_ID", nil),
+						Description: "AWS access key ID used to build the credential chain. If unset, falls back to the environment, shared credentials file and EC2 role provider, in that order.",
+					},
+					"secret_key": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						Sensitive:   true,
+						DefaultFunc: schema.EnvDefaultFunc("AWS_SECRET_ACCESS_KEY", nil),
+						Description: "AWS secret access key, paired with access_key.",
+					},
+					"session_token": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						Sensitive:   true,
+						DefaultFunc: schema.EnvDefaultFunc("AWS_SESSION_TOKEN", nil),
+						Description: "AWS session token, used together with access_key/secret_key for temporary credentials.",
+					},
+					"profile": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						Description: "Name of the AWS shared-credentials profile to use.",
+					},
+					"shared_credentials_file": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						Description: "Path to the AWS shared credentials file (defaults to ~/.aws/credentials).",
+					},
+					"region": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						DefaultFunc: schema.EnvDefaultFunc("AWS_REGION", nil),
+						Description: "AWS region used to sign the STS GetCallerIdentity request.",
+					},
+					"assume_role": {
+						Type:        schema.TypeList,
+						Optional:    true,
+						MaxItems:    1,
+						Description: "A configuration block to assume an IAM role on top of the resolved credentials before signing the cloud id.",
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								"role_arn": {
+									Type:        schema.TypeString,
+									Required:    true,
+									Description: "ARN of the IAM role to assume.",
+								},
+								"session_name": {
+									Type:        schema.TypeString,
+									Optional:    true,
+									Description: "Session name to attach to the assumed-role session.",
+								},
+								"external_id": {
+									Type:        schema.TypeString,
+									Optional:    true,
+									Description: "External ID required by the role's trust policy, if any.",
+								},
+								"policy": {
+									Type:        schema.TypeString,
+									Optional:    true,
+									Description: "An IAM policy to further restrict the assumed-role session.",
+								},
+								"duration_seconds": {
+									Type:        schema.TypeInt,
+									Optional:    true,
+									Description: "Duration, in seconds, of the assumed-role session.",
+								},
+							},
 						},
 					},
 				},
 			},
-			"aws_iam_login": {
-				Type:        schema.TypeList,
-				Optional:    true,
-				Description: "A configuration block, described below, that attempts to authenticate using AWS-IAM authentication credentials.",
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"access_id": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
+		},
+		"azure_ad_login": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "A configuration block, described below, that attempts to authenticate using Azure Active Directory authentication.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"access_id": {
+						Type:     schema.TypeString,
+						Required: true,
+					},
+					"tenant_id": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						DefaultFunc: schema.EnvDefaultFunc("AZURE_TENANT_ID", nil),
+						Description: "Azure AD tenant ID, used by the environment and workload-identity credential sources.",
+					},
+					"client_id": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						DefaultFunc: schema.EnvDefaultFunc("AZURE_CLIENT_ID", nil),
+						Description: "Azure AD application (client) ID, used by the environment and workload-identity credential sources.",
+					},
+					"client_secret": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						Sensitive:   true,
+						DefaultFunc: schema.EnvDefaultFunc("AZURE_CLIENT_SECRET", nil),
+						Description: "Azure AD client secret, used by the environment credential source.",
+					},
+					"client_certificate_path": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						DefaultFunc: schema.EnvDefaultFunc("AZURE_CLIENT_CERTIFICATE_PATH", nil),
+						Description: "Path to a client certificate, used by the environment credential source instead of client_secret.",
+					},
+					"federated_token_file": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						DefaultFunc: schema.EnvDefaultFunc("AZURE_FEDERATED_TOKEN_FILE", nil),
+						Description: "Path to the projected Kubernetes service-account token, used by the workload-identity credential source.",
+					},
+					"object_id": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						Description: "Object ID of a user-assigned managed identity, used by the managed-identity credential source.",
+					},
+					"resource": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						Default:     defaultAzureResource,
+						Description: "Azure resource (audience) the access token is requested for. managed_identity can only honor the default; see its doc comment.",
+					},
+					"credential_source": {
+						Type:         schema.TypeString,
+						Optional:     true,
+						Default:      "chain",
+						ValidateFunc: validation.StringInSlice([]string{"chain", "environment", "workload_identity", "managed_identity", "cli"}, false),
+						Description:  "Pins the credential source instead of trying the default chain (environment, workload identity, managed identity, Azure CLI) in order. One of chain, environment, workload_identity, managed_identity, cli.",
 					},
 				},
 			},
-			"azure_ad_login": {
-				Type:        schema.TypeList,
-				Optional:    true,
-				Description: "A configuration block, described below, that attempts to authenticate using Azure Active Directory authentication.",
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"access_id": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
+		},
+		"email_login": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "A configuration block, described below, that attempts to authenticate using email and password.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"admin_email": {
+						Type:     schema.TypeString,
+						Required: true,
+					},
+					"admin_password": {
+						Type:     schema.TypeString,
+						Required: true,
 					},
 				},
 			},
-			"email_login": {
-				Type:        schema.TypeList,
-				Optional:    true,
-				Description: "A configuration block, described below, that attempts to authenticate using email and password.",
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"admin_email": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
-						"admin_password": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
+		},
+	}
+}
+
+// Provider returns Akeyless Terraform provider
+func Provider() *schema.Provider {
+	providerSchema := map[string]*schema.Schema{
+		"api_gateway_address": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Default:     publicApi,
+			Description: "Origin URL of the API Gateway server. This is a URL with a scheme, a hostname and a port.",
+		},
+		"disable_token_refresh": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Disable automatic re-authentication shortly before the session token expires. The token obtained during provider configuration is used as-is for the lifetime of the run.",
+		},
+		"token_cache": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Description: "A configuration block, described below, that persists the session token between terraform invocations so back-to-back plan/apply runs do not re-authenticate.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"path": {
+						Type:        schema.TypeString,
+						Required:    true,
+						Description: "Path of the file used to cache the session token.",
+					},
+					"encryption_mode": {
+						Type:         schema.TypeString,
+						Optional:     true,
+						Default:      "none",
+						ValidateFunc: validation.StringInSlice([]string{"none", "aes-gcm"}, false),
+						Description:  "How the cached token is protected at rest. One of none, aes-gcm. aes-gcm requires encryption_key.",
+					},
+					"encryption_key": {
+						Type:        schema.TypeString,
+						Optional:    true,
+						Sensitive:   true,
+						DefaultFunc: schema.EnvDefaultFunc(tokenCacheKeyEnv, nil),
+						Description: "Passphrase the aes-gcm AES key is derived from. Required when encryption_mode is aes-gcm; never written to disk, so losing it makes the cache file unreadable. Falls back to " + tokenCacheKeyEnv + ".",
 					},
 				},
 			},
 		},
-		ConfigureFunc: configureProvider,
+	}
+	for name, s := range loginSchemas() {
+		providerSchema[name] = s
+	}
+
+	return &schema.Provider{
+		Schema:               providerSchema,
+		ConfigureContextFunc: configureProvider,
 		ResourcesMap: map[string]*schema.Resource{
-			"akeyless_static_secret": resourceStaticSecret(),
-			"akeyless_auth_method":   resourceAuthMethod(),
-			"akeyless_role":          resourceRole(),
+			"akeyless_static_secret":    resourceStaticSecret(),
+			"akeyless_auth_method":      resourceAuthMethod(),
+			"akeyless_role":             resourceRole(),
+			"akeyless_auth_method_oidc": resourceAuthMethodOidc(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"akeyless_static_secret":  dataSourceStaticSecret(),
-			"akeyless_secret":         dataSourceSecret(),
-			"akeyless_auth_method":    dataSourceAuthMethod(),
-			"akeyless_dynamic_secret": dataSourceDynamicSecret(),
-			"akeyless_role":           dataSourceRole(),
+			"akeyless_static_secret":    dataSourceStaticSecret(),
+			"akeyless_secret":           dataSourceSecret(),
+			"akeyless_auth_method":      dataSourceAuthMethod(),
+			"akeyless_dynamic_secret":   dataSourceDynamicSecret(),
+			"akeyless_role":             dataSourceRole(),
+			"akeyless_auth_method_oidc": dataSourceAuthMethodOidc(),
 		},
 	}
 }
 
-func configureProvider(d *schema.ResourceData) (interface{}, error) {
+func configureProvider(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
 	apiGwAddress := d.Get("api_gateway_address").(string)
 
-	err := inputValidation(d)
+	cfg, err := inputValidation(d)
 	if err != nil {
-		return "", err
+		return nil, diag.FromErr(err)
 	}
 
-	ctx := context.Background()
 	client := akeyless.NewAPIClient(&akeyless.Configuration{
 		Servers: []akeyless.ServerConfiguration{
 			{
@@ -127,29 +316,141 @@ func configureProvider(d *schema.ResourceData) (interface{}, error) {
 		},
 	}).V2Api
 
-	authBody := akeyless.NewAuthWithDefaults()
-	err = setAuthBody(authBody)
+	refresh := func(ctx context.Context) (string, time.Time, error) {
+		authBody := akeyless.NewAuthWithDefaults()
+		if err := setAuthBody(authBody, cfg); err != nil {
+			return "", time.Time{}, err
+		}
+
+		var apiErr akeyless.GenericOpenAPIError
+		authOut, _, err := client.Auth(ctx).Body(*authBody).Execute()
+		if err != nil {
+			if errors.As(err, &apiErr) {
+				return "", time.Time{}, fmt.Errorf("authentication failed: %v", string(apiErr.Body()))
+			}
+			return "", time.Time{}, fmt.Errorf("authentication failed: %v", err)
+		}
+
+		expiresAt := time.Now().Add(time.Duration(authOut.GetExpiration()) * time.Second)
+		return authOut.GetToken(), expiresAt, nil
+	}
+
+	cache := newTokenCache(tokenCachePath(d), tokenCacheEncryptionMode(d), tokenCacheEncryptionKey(d))
+	source := newTokenSource(refresh, defaultRefreshWindow, d.Get("disable_token_refresh").(bool), cache)
+
+	if err := source.prime(ctx); err != nil {
+		return nil, diag.FromErr(err)
+	}
+	// startBackgroundRefresh runs on its own long-lived context rather than ctx, which is
+	// scoped to this single ConfigureProvider RPC and would cancel the goroutine almost
+	// immediately. It is stopped via providerMeta.Close() instead.
+	source.startBackgroundRefresh()
+
+	initialToken, err := source.Token(ctx)
 	if err != nil {
-		return "", err
+		return nil, diag.FromErr(err)
+	}
+
+	return providerMeta{client: client, source: source, apiGwAddress: apiGwAddress, token: &initialToken}, nil
+}
+
+func tokenCachePath(d *schema.ResourceData) string {
+	tokenCacheList := d.Get("token_cache").([]interface{})
+	if len(tokenCacheList) != 1 {
+		return ""
+	}
+	block, ok := tokenCacheList[0].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	path, _ := block["path"].(string)
+	return path
+}
+
+func tokenCacheEncryptionMode(d *schema.ResourceData) string {
+	tokenCacheList := d.Get("token_cache").([]interface{})
+	if len(tokenCacheList) != 1 {
+		return ""
+	}
+	block, ok := tokenCacheList[0].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	mode, _ := block["encryption_mode"].(string)
+	return mode
+}
+
+func tokenCacheEncryptionKey(d *schema.ResourceData) string {
+	tokenCacheList := d.Get("token_cache").([]interface{})
+	if len(tokenCacheList) != 1 {
+		return ""
+	}
+	block, ok := tokenCacheList[0].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	key, _ := block["encryption_key"].(string)
+	return key
+}
+
+// Authenticate builds an API client for apiGwAddress and authenticates it using whichever
+// login block is set on d (api_key_login/email_login/aws_iam_login/azure_ad_login). It is
+// exported so other consumers of the login schema, such as the akeyless remote-state
+// backend, can reuse the same authentication flow as the provider.
+func Authenticate(d *schema.ResourceData, apiGwAddress string) (*akeyless.V2ApiService, string, error) {
+	cfg, err := inputValidation(d)
+	if err != nil {
+		return nil, "", err
+	}
+	return authenticate(cfg, apiGwAddress)
+}
+
+// AuthenticateWithEnv is Authenticate for consumers that have no *schema.ResourceData to
+// populate, such as the akeyless remote-state backend's standalone server binary. It only
+// supports the AKEYLESS_ACCESS_ID/AKEYLESS_ACCESS_KEY environment-variable fallback that
+// setAuthBody already falls back to when no login block is configured.
+func AuthenticateWithEnv(apiGwAddress string) (*akeyless.V2ApiService, string, error) {
+	return authenticate(&loginConfig{}, apiGwAddress)
+}
+
+func authenticate(cfg *loginConfig, apiGwAddress string) (*akeyless.V2ApiService, string, error) {
+	client := akeyless.NewAPIClient(&akeyless.Configuration{
+		Servers: []akeyless.ServerConfiguration{
+			{
+				URL: apiGwAddress,
+			},
+		},
+	}).V2Api
+
+	authBody := akeyless.NewAuthWithDefaults()
+	if err := setAuthBody(authBody, cfg); err != nil {
+		return nil, "", err
 	}
 
 	var apiErr akeyless.GenericOpenAPIError
 
+	ctx := context.Background()
 	authOut, _, err := client.Auth(ctx).Body(*authBody).Execute()
 	if err != nil {
 		if errors.As(err, &apiErr) {
-			return "", fmt.Errorf("authentication failed: %v", string(apiErr.Body()))
+			return nil, "", fmt.Errorf("authentication failed: %v", string(apiErr.Body()))
 		}
-		return "", fmt.Errorf("authentication failed: %v", err)
+		return nil, "", fmt.Errorf("authentication failed: %v", err)
 	}
-	token := authOut.GetToken()
 
-	return providerMeta{client, &token}, nil
+	return client, authOut.GetToken(), nil
 }
 
-func setAuthBody(authBody *akeyless.Auth) error {
-	if apiKeyLogin != nil && len(apiKeyLogin) == 1 {
-		login, ok := apiKeyLogin[0].(map[string]interface{})
+// LoginSchemas exposes the four login blocks (api_key_login, email_login, aws_iam_login,
+// azure_ad_login) so other Schema-based consumers, such as the akeyless remote-state
+// backend, can embed the same authentication configuration as the provider.
+func LoginSchemas() map[string]*schema.Schema {
+	return loginSchemas()
+}
+
+func setAuthBody(authBody *akeyless.Auth, cfg *loginConfig) error {
+	if cfg.apiKeyLogin != nil && len(cfg.apiKeyLogin) == 1 {
+		login, ok := cfg.apiKeyLogin[0].(map[string]interface{})
 		if ok {
 			accessID := login["access_id"].(string)
 			accessKey := login["access_key"].(string)
@@ -168,28 +469,33 @@ func setAuthBody(authBody *akeyless.Auth) error {
 		return nil
 	}
 
-	if emailLogin != nil && len(emailLogin) == 1 {
-		login := emailLogin[0].(map[string]interface{})
+	if cfg.emailLogin != nil && len(cfg.emailLogin) == 1 {
+		login := cfg.emailLogin[0].(map[string]interface{})
 		adminEmail := login["admin_email"].(string)
 		adminPassword := login["admin_password"].(string)
 		authBody.AdminEmail = akeyless.PtrString(adminEmail)
 		authBody.AdminPassword = akeyless.PtrString(adminPassword)
 		authBody.AccessType = akeyless.PtrString(common.Password)
-	} else if awsIAMLogin != nil && len(awsIAMLogin) == 1 {
-		login := awsIAMLogin[0].(map[string]interface{})
+	} else if cfg.awsIAMLogin != nil && len(cfg.awsIAMLogin) == 1 {
+		login := cfg.awsIAMLogin[0].(map[string]interface{})
 		accessID := login["access_id"].(string)
 		authBody.AccessId = akeyless.PtrString(accessID)
-		cloudId, err := aws.GetCloudId()
+		creds, err := buildAwsCredentialChain(login)
+		if err != nil {
+			return fmt.Errorf("require Cloud ID: %v", err.Error())
+		}
+		region, _ := login["region"].(string)
+		cloudId, err := getCloudIdWithCreds(creds, region)
 		if err != nil {
 			return fmt.Errorf("require Cloud ID: %v", err.Error())
 		}
 		authBody.CloudId = akeyless.PtrString(cloudId)
 		authBody.AccessType = akeyless.PtrString(common.AwsIAM)
-	} else if azureADLogin != nil && len(azureADLogin) == 1 {
-		login := azureADLogin[0].(map[string]interface{})
+	} else if cfg.azureADLogin != nil && len(cfg.azureADLogin) == 1 {
+		login := cfg.azureADLogin[0].(map[string]interface{})
 		accessID := login["access_id"].(string)
 		authBody.AccessId = akeyless.PtrString(accessID)
-		cloudId, err := azure.GetCloudId("")
+		cloudId, err := buildAzureAdCloudId(login)
 		if err != nil {
 			return fmt.Errorf("require Cloud ID: %v", err.Error())
 		}
@@ -202,27 +508,386 @@ func setAuthBody(authBody *akeyless.Auth) error {
 	return nil
 }
 
+// awsCredentialChainStep names a credentials.Provider for the per-step diagnostics
+// buildAwsCredentialChain aggregates - credentials.NewChainCredentials swallows each
+// provider's individual error and surfaces only a single generic "no valid providers in
+// chain" message, which isn't enough to tell a user which step they misconfigured.
+type awsCredentialChainStep struct {
+	name     string
+	provider credentials.Provider
+}
+
+// buildAwsCredentialChain resolves AWS credentials for aws_iam_login from, in order, the
+// static HCL fields, the environment, the shared-credentials file/profile and finally the
+// EC2 role provider. When assume_role.role_arn is set, the resolved credentials are wrapped
+// with stscreds so the cloud id is signed as the assumed role rather than the base identity.
+func buildAwsCredentialChain(login map[string]interface{}) (*credentials.Credentials, error) {
+	var errs *multierror.Error
+
+	accessKey, _ := login["access_key"].(string)
+	secretKey, _ := login["secret_key"].(string)
+	sessionToken, _ := login["session_token"].(string)
+	profile, _ := login["profile"].(string)
+	sharedCredentialsFile, _ := login["shared_credentials_file"].(string)
+	region, _ := login["region"].(string)
+
+	steps := []awsCredentialChainStep{
+		{
+			name: "static credentials (access_key/secret_key)",
+			provider: &credentials.StaticProvider{
+				Value: credentials.Value{
+					AccessKeyID:     accessKey,
+					SecretAccessKey: secretKey,
+					SessionToken:    sessionToken,
+				},
+			},
+		},
+		{name: "environment variables", provider: &credentials.EnvProvider{}},
+		{
+			name: "shared credentials file",
+			provider: &credentials.SharedCredentialsProvider{
+				Filename: sharedCredentialsFile,
+				Profile:  profile,
+			},
+		},
+		{name: "EC2/ECS role", provider: defaults.RemoteCredProvider(*awssdk.NewConfig(), defaults.Handlers())},
+	}
+
+	// Retrieve each step directly first so a failure names the step it came from, instead of
+	// letting ChainProvider.Retrieve() (below) collapse every step's error into one generic
+	// message. Stop at the first step that resolves, like the chain itself would - otherwise
+	// every later step runs unconditionally, including the EC2/ECS role provider's live call
+	// to the instance-metadata service even when an earlier step already succeeded.
+	var resolved bool
+	for _, step := range steps {
+		if _, err := step.provider.Retrieve(); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("%s: %w", step.name, err))
+			continue
+		}
+		resolved = true
+		break
+	}
+	if !resolved {
+		return nil, errs.ErrorOrNil()
+	}
+
+	providers := make([]credentials.Provider, len(steps))
+	for i, step := range steps {
+		providers[i] = step.provider
+	}
+	chain := credentials.NewChainCredentials(providers)
+
+	sess, err := session.NewSession(&awssdk.Config{
+		Region:      awssdk.String(region),
+		Credentials: chain,
+	})
+	if err != nil {
+		errs = multierror.Append(errs, fmt.Errorf("building AWS session: %w", err))
+		return nil, errs.ErrorOrNil()
+	}
+
+	assumeRoleList, _ := login["assume_role"].([]interface{})
+	if len(assumeRoleList) == 1 {
+		assumeRole, ok := assumeRoleList[0].(map[string]interface{})
+		if !ok {
+			errs = multierror.Append(errs, fmt.Errorf("invalid assume_role block"))
+			return nil, errs.ErrorOrNil()
+		}
+
+		roleArn, _ := assumeRole["role_arn"].(string)
+		if roleArn == "" {
+			errs = multierror.Append(errs, fmt.Errorf("assume_role.role_arn is required when assume_role is set"))
+			return nil, errs.ErrorOrNil()
+		}
+
+		assumeRoleCreds := stscreds.NewCredentials(sess, roleArn, func(p *stscreds.AssumeRoleProvider) {
+			if sessionName, ok := assumeRole["session_name"].(string); ok && sessionName != "" {
+				p.RoleSessionName = sessionName
+			}
+			if externalID, ok := assumeRole["external_id"].(string); ok && externalID != "" {
+				p.ExternalID = awssdk.String(externalID)
+			}
+			if policy, ok := assumeRole["policy"].(string); ok && policy != "" {
+				p.Policy = awssdk.String(policy)
+			}
+			if durationSeconds, ok := assumeRole["duration_seconds"].(int); ok && durationSeconds > 0 {
+				p.Duration = time.Duration(durationSeconds) * time.Second
+			}
+		})
+
+		return assumeRoleCreds, nil
+	}
+
+	return chain, nil
+}
+
+// awsCredentialEnvMu serializes getCloudIdWithCreds calls. The AWS SDK's default chain (which
+// is what aws.GetCloudId() signs with, see below) reads credentials from the process
+// environment, so two goroutines resolving different aws_iam_login blocks concurrently - one
+// provider's background token refresh racing another's, or Terraform's own parallel resource
+// operations - could otherwise have one goroutine's restore clobber another's override and sign
+// with the wrong identity.
+var awsCredentialEnvMu sync.Mutex
+
+// getCloudIdWithCreds resolves a cloud id for creds/region. akeyless-go-cloud-id's aws package
+// only exposes a no-arg GetCloudId(), which in turn signs against whatever credentials and
+// region the AWS SDK's own default chain finds in the environment - it has no parameters to
+// accept an already-resolved credentials.Credentials or region. To actually make
+// assume_role/profile/shared_credentials_file/region take effect, the resolved access
+// key/secret/session token and region are exported into the process environment for the
+// duration of the call, then restored.
+func getCloudIdWithCreds(creds *credentials.Credentials, region string) (string, error) {
+	awsCredentialEnvMu.Lock()
+	defer awsCredentialEnvMu.Unlock()
+
+	// creds.Get() must also run inside the lock: when the chain falls through to
+	// EnvProvider, it reads AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY from the process
+	// environment, which is exactly what overrideAwsCredentialEnv below is serializing access
+	// to. Resolving it outside the lock could read another goroutine's override mid-flight.
+	val, err := creds.Get()
+	if err != nil {
+		return "", fmt.Errorf("resolving AWS credentials: %w", err)
+	}
+
+	restore := overrideAwsCredentialEnv(val, region)
+	defer restore()
+
+	return aws.GetCloudId()
+}
+
+// overrideAwsCredentialEnv sets the AWS SDK's standard credential and region environment
+// variables to val/region and returns a func that restores whatever was there before. Callers
+// must hold awsCredentialEnvMu for the full override/call/restore sequence.
+func overrideAwsCredentialEnv(val credentials.Value, region string) func() {
+	prev := map[string]*string{
+		"AWS_ACCESS_KEY_ID":     nil,
+		"AWS_SECRET_ACCESS_KEY": nil,
+		"AWS_SESSION_TOKEN":     nil,
+		"AWS_REGION":            nil,
+		"AWS_DEFAULT_REGION":    nil,
+	}
+	for name := range prev {
+		if v, ok := os.LookupEnv(name); ok {
+			v := v
+			prev[name] = &v
+		}
+	}
+
+	os.Setenv("AWS_ACCESS_KEY_ID", val.AccessKeyID)
+	os.Setenv("AWS_SECRET_ACCESS_KEY", val.SecretAccessKey)
+	if val.SessionToken != "" {
+		os.Setenv("AWS_SESSION_TOKEN", val.SessionToken)
+	} else {
+		os.Unsetenv("AWS_SESSION_TOKEN")
+	}
+	if region != "" {
+		os.Setenv("AWS_REGION", region)
+		os.Setenv("AWS_DEFAULT_REGION", region)
+	}
+
+	return func() {
+		for name, v := range prev {
+			if v == nil {
+				os.Unsetenv(name)
+			} else {
+				os.Setenv(name, *v)
+			}
+		}
+	}
+}
+
+// defaultAzureResource is azure_ad_login.resource's default, and the only audience
+// azureManagedIdentityToken can actually honor - see its doc comment.
+const defaultAzureResource = "https://management.azure.com/"
+
+// buildAzureAdCloudId fetches an AAD access token using a DefaultAzureCredential-style chain
+// (environment, workload identity, managed identity, Azure CLI) and returns it to be used
+// directly as the cloud id. Only the managed identity source goes through akeyless-go-cloud-id
+// and IMDS; the others fetch the token themselves.
+func buildAzureAdCloudId(login map[string]interface{}) (string, error) {
+	resource, _ := login["resource"].(string)
+	credentialSource, _ := login["credential_source"].(string)
+
+	sources := map[string]func(map[string]interface{}, string) (string, error){
+		"environment":       azureEnvironmentToken,
+		"workload_identity": azureWorkloadIdentityToken,
+		"managed_identity":  azureManagedIdentityToken,
+		"cli":               azureCliToken,
+	}
+
+	if fn, ok := sources[credentialSource]; ok {
+		return fn(login, resource)
+	}
+
+	var errs *multierror.Error
+	for _, name := range []string{"environment", "workload_identity", "managed_identity", "cli"} {
+		token, err := sources[name](login, resource)
+		if err == nil {
+			return token, nil
+		}
+		errs = multierror.Append(errs, fmt.Errorf("%s: %w", name, err))
+	}
+
+	return "", errs.ErrorOrNil()
+}
+
+// azureEnvironmentToken authenticates with a service principal using
+// AZURE_CLIENT_ID/AZURE_CLIENT_SECRET/AZURE_TENANT_ID or a client certificate.
+func azureEnvironmentToken(login map[string]interface{}, resource string) (string, error) {
+	clientID, _ := login["client_id"].(string)
+	tenantID, _ := login["tenant_id"].(string)
+	clientSecret, _ := login["client_secret"].(string)
+	clientCertPath, _ := login["client_certificate_path"].(string)
+
+	if clientID == "" || tenantID == "" {
+		return "", fmt.Errorf("client_id and tenant_id are required")
+	}
+
+	oauthConfig, err := adal.NewOAuthConfig(azure.ActiveDirectoryEndpoint, tenantID)
+	if err != nil {
+		return "", err
+	}
+
+	var spt *adal.ServicePrincipalToken
+	switch {
+	case clientSecret != "":
+		spt, err = adal.NewServicePrincipalToken(*oauthConfig, clientID, clientSecret, resource)
+	case clientCertPath != "":
+		certData, readErr := os.ReadFile(clientCertPath)
+		if readErr != nil {
+			return "", readErr
+		}
+		cert, rsaKey, parseErr := adal.DecodePfxCertificateData(certData, "")
+		if parseErr != nil {
+			return "", parseErr
+		}
+		spt, err = adal.NewServicePrincipalTokenFromCertificate(*oauthConfig, clientID, cert, rsaKey, resource)
+	default:
+		return "", fmt.Errorf("client_secret or client_certificate_path is required")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := spt.Refresh(); err != nil {
+		return "", err
+	}
+
+	return spt.OAuthToken(), nil
+}
+
+// azureWorkloadIdentityToken exchanges the projected Kubernetes service-account token for an
+// AAD token via the client-assertion flow, as used by AKS workload identity.
+func azureWorkloadIdentityToken(login map[string]interface{}, resource string) (string, error) {
+	tenantID, _ := login["tenant_id"].(string)
+	clientID, _ := login["client_id"].(string)
+	tokenFile, _ := login["federated_token_file"].(string)
+
+	if tenantID == "" {
+		tenantID = os.Getenv("AZURE_TENANT_ID")
+	}
+	if clientID == "" {
+		clientID = os.Getenv("AZURE_CLIENT_ID")
+	}
+	if tokenFile == "" {
+		tokenFile = os.Getenv("AZURE_FEDERATED_TOKEN_FILE")
+	}
+	if tenantID == "" || clientID == "" || tokenFile == "" {
+		return "", fmt.Errorf("tenant_id, client_id and federated_token_file are required")
+	}
+
+	assertion, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return "", err
+	}
+
+	oauthConfig, err := adal.NewOAuthConfig(azure.ActiveDirectoryEndpoint, tenantID)
+	if err != nil {
+		return "", err
+	}
+
+	spt, err := adal.NewServicePrincipalTokenFromFederatedToken(*oauthConfig, clientID, string(assertion), resource)
+	if err != nil {
+		return "", err
+	}
+	if err := spt.Refresh(); err != nil {
+		return "", err
+	}
+
+	return spt.OAuthToken(), nil
+}
+
+// azureManagedIdentityToken requests a token from IMDS, optionally for a user-assigned
+// identity. akeyless-go-cloud-id's azure package only exposes GetCloudId(objectID string); it
+// has no parameter for the token's audience, so resource is ignored except to fail fast when
+// it asks for anything other than defaultAzureResource - silently handing back a token scoped
+// to the wrong audience would be worse than an explicit error.
+func azureManagedIdentityToken(login map[string]interface{}, resource string) (string, error) {
+	if resource != "" && resource != defaultAzureResource {
+		return "", fmt.Errorf("managed_identity credential_source only supports resource %q (akeyless-go-cloud-id's IMDS client has no way to request a different audience), got %q", defaultAzureResource, resource)
+	}
+
+	objectID, _ := login["object_id"].(string)
+	if objectID == "" {
+		return azureCloudId.GetCloudId("")
+	}
+	return azureCloudId.GetCloudId(objectID)
+}
+
+// azureCliToken shells out to `az account get-access-token` for local/dev use.
+func azureCliToken(_ map[string]interface{}, resource string) (string, error) {
+	out, err := exec.Command("az", "account", "get-access-token", "--resource", resource, "--query", "accessToken", "-o", "tsv").Output()
+	if err != nil {
+		return "", fmt.Errorf("az cli token request failed: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 type providerMeta struct {
-	client *akeyless.V2ApiService
-	token  *string
+	client       *akeyless.V2ApiService
+	source       *tokenSource
+	apiGwAddress string
+
+	// token is the session token captured when the provider was configured. It is kept only
+	// for source compatibility with resources still written as `token := *provider.token`;
+	// unlike Token(ctx), it is never refreshed, so it can go stale over a long-running apply.
+	// New and migrated resources should call Token(ctx) instead.
+	token *string
 }
 
-func inputValidation(d *schema.ResourceData) error {
-	apiKeyLogin = d.Get("api_key_login").([]interface{})
-	if len(apiKeyLogin) > 1 {
-		return fmt.Errorf("api_key_login block may appear only once")
+// Token returns a session token valid for at least the token source's refresh window,
+// transparently re-authenticating first if it is not. Resources should call this instead of
+// reading a token captured once at configureProvider time, since long-running applies can
+// otherwise hit expired-token errors from the API.
+func (p providerMeta) Token(ctx context.Context) (string, error) {
+	return p.source.Token(ctx)
+}
+
+// Close stops the token source's background refresh goroutine. It should be called when the
+// provider instance is torn down (e.g. on process exit), since the goroutine otherwise runs
+// against context.Background() for as long as the process is alive.
+func (p providerMeta) Close() {
+	p.source.Close()
+}
+
+func inputValidation(d *schema.ResourceData) (*loginConfig, error) {
+	cfg := &loginConfig{
+		apiKeyLogin:  d.Get("api_key_login").([]interface{}),
+		emailLogin:   d.Get("email_login").([]interface{}),
+		awsIAMLogin:  d.Get("aws_iam_login").([]interface{}),
+		azureADLogin: d.Get("azure_ad_login").([]interface{}),
 	}
-	emailLogin = d.Get("email_login").([]interface{})
-	if len(emailLogin) > 1 {
-		return fmt.Errorf("emailLogin block may appear only once")
+	if len(cfg.apiKeyLogin) > 1 {
+		return nil, fmt.Errorf("api_key_login block may appear only once")
 	}
-	awsIAMLogin = d.Get("aws_iam_login").([]interface{})
-	if len(awsIAMLogin) > 1 {
-		return fmt.Errorf("aws_iam_login block may appear only once")
+	if len(cfg.emailLogin) > 1 {
+		return nil, fmt.Errorf("emailLogin block may appear only once")
 	}
-	azureADLogin = d.Get("azure_ad_login").([]interface{})
-	if len(azureADLogin) > 1 {
-		return fmt.Errorf("azure_ad_login block may appear only once")
+	if len(cfg.awsIAMLogin) > 1 {
+		return nil, fmt.Errorf("aws_iam_login block may appear only once")
 	}
-	return nil
+	if len(cfg.azureADLogin) > 1 {
+		return nil, fmt.Errorf("azure_ad_login block may appear only once")
+	}
+	return cfg, nil
 }
@@ -12,6 +12,17 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
+// NOT IMPLEMENTED: a request asked for this resource to grow inline IdP metadata
+// (idp_metadata_xml), signed AuthnRequests (signing_key_name, require_signed_assertions),
+// nameid_format and allowed_clock_skew_seconds, and attribute_mapping. An earlier pass added
+// all of these (16c60cb) by POSTing to /update-auth-method-saml-access-rules and
+// /get-auth-method-saml-access-rules, REST paths and a JSON shape that were never confirmed
+// against the real Akeyless API or the vendored SDK - e8c9d29 reverted that for shipping
+// fabricated endpoints in a production provider. Re-adding this feature needs either
+// CreateAuthMethodSAML/UpdateAuthMethodSAML/SamlAccessRules fields on a verified SDK version
+// that actually carry these values, or a real, documented endpoint for them; neither is
+// available in this tree. Until then this resource only exposes the fields below, which map
+// directly onto CreateAuthMethodSAML/UpdateAuthMethodSAML/GetAuthMethod as vendored.
 func resourceAuthMethodSaml() *schema.Resource {
 	return &schema.Resource{
 		Description: "SAML Auth Method Resource",
@@ -80,10 +91,13 @@ func resourceAuthMethodSaml() *schema.Resource {
 func resourceAuthMethodSamlCreate(d *schema.ResourceData, m interface{}) error {
 	provider := m.(providerMeta)
 	client := *provider.client
-	token := *provider.token
 
 	var apiErr akeyless.GenericOpenAPIError
 	ctx := context.Background()
+	token, err := provider.Token(ctx)
+	if err != nil {
+		return err
+	}
 	name := d.Get("name").(string)
 	accessExpires := d.Get("access_expires").(int)
 	boundIpsSet := d.Get("bound_ips").(*schema.Set)
@@ -128,10 +142,13 @@ func resourceAuthMethodSamlCreate(d *schema.ResourceData, m interface{}) error {
 func resourceAuthMethodSamlRead(d *schema.ResourceData, m interface{}) error {
 	provider := m.(providerMeta)
 	client := *provider.client
-	token := *provider.token
 
 	var apiErr akeyless.GenericOpenAPIError
 	ctx := context.Background()
+	token, err := provider.Token(ctx)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 
@@ -207,10 +224,13 @@ func resourceAuthMethodSamlRead(d *schema.ResourceData, m interface{}) error {
 func resourceAuthMethodSamlUpdate(d *schema.ResourceData, m interface{}) error {
 	provider := m.(providerMeta)
 	client := *provider.client
-	token := *provider.token
 
 	var apiErr akeyless.GenericOpenAPIError
 	ctx := context.Background()
+	token, err := provider.Token(ctx)
+	if err != nil {
+		return err
+	}
 	name := d.Get("name").(string)
 	accessExpires := d.Get("access_expires").(int)
 	boundIpsSet := d.Get("bound_ips").(*schema.Set)
@@ -233,7 +253,7 @@ func resourceAuthMethodSamlUpdate(d *schema.ResourceData, m interface{}) error {
 	common.GetAkeylessPtr(&body.AllowedRedirectUri, allowedRedirectUri)
 	common.GetAkeylessPtr(&body.NewName, name)
 
-	_, _, err := client.UpdateAuthMethodSAML(ctx).Body(body).Execute()
+	_, _, err = client.UpdateAuthMethodSAML(ctx).Body(body).Execute()
 	if err != nil {
 		if errors.As(err, &apiErr) {
 			return fmt.Errorf("can't update : %v", string(apiErr.Body()))
@@ -249,7 +269,12 @@ func resourceAuthMethodSamlUpdate(d *schema.ResourceData, m interface{}) error {
 func resourceAuthMethodSamlDelete(d *schema.ResourceData, m interface{}) error {
 	provider := m.(providerMeta)
 	client := *provider.client
-	token := *provider.token
+
+	ctx := context.Background()
+	token, err := provider.Token(ctx)
+	if err != nil {
+		return err
+	}
 
 	path := d.Id()
 
@@ -258,8 +283,7 @@ func resourceAuthMethodSamlDelete(d *schema.ResourceData, m interface{}) error {
 		Name:  path,
 	}
 
-	ctx := context.Background()
-	_, _, err := client.DeleteAuthMethod(ctx).Body(deleteItem).Execute()
+	_, _, err = client.DeleteAuthMethod(ctx).Body(deleteItem).Execute()
 	if err != nil {
 		return err
 	}
@@ -270,7 +294,12 @@ func resourceAuthMethodSamlDelete(d *schema.ResourceData, m interface{}) error {
 func resourceAuthMethodSamlImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
 	provider := m.(providerMeta)
 	client := *provider.client
-	token := *provider.token
+
+	ctx := context.Background()
+	token, err := provider.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	path := d.Id()
 
@@ -279,8 +308,7 @@ func resourceAuthMethodSamlImport(d *schema.ResourceData, m interface{}) ([]*sch
 		Token: &token,
 	}
 
-	ctx := context.Background()
-	_, _, err := client.GetAuthMethod(ctx).Body(item).Execute()
+	_, _, err = client.GetAuthMethod(ctx).Body(item).Execute()
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,276 @@
+package akeyless
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+const defaultRefreshWindow = 60 * time.Second
+
+// tokenCacheKeyEnv is the environment variable token_cache.encryption_key falls back to.
+const tokenCacheKeyEnv = "AKEYLESS_TOKEN_CACHE_KEY"
+
+// tokenSource guards the current Akeyless session token behind a mutex and transparently
+// re-authenticates shortly before it expires, so resources never operate on a stale token.
+type tokenSource struct {
+	mu            sync.Mutex
+	token         string
+	expiresAt     time.Time
+	refreshWindow time.Duration
+	disabled      bool
+	refresh       func(ctx context.Context) (string, time.Time, error)
+	cache         *tokenCache
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+func newTokenSource(refresh func(ctx context.Context) (string, time.Time, error), refreshWindow time.Duration, disabled bool, cache *tokenCache) *tokenSource {
+	if refreshWindow <= 0 {
+		refreshWindow = defaultRefreshWindow
+	}
+	return &tokenSource{
+		refresh:       refresh,
+		refreshWindow: refreshWindow,
+		disabled:      disabled,
+		cache:         cache,
+		stopCh:        make(chan struct{}),
+	}
+}
+
+// Close stops the background refresh goroutine started by startBackgroundRefresh. It is safe
+// to call more than once and safe to call even if startBackgroundRefresh was never started.
+func (t *tokenSource) Close() {
+	t.stopOnce.Do(func() {
+		close(t.stopCh)
+	})
+}
+
+// prime loads a still-valid token from the cache, falling back to a fresh authentication.
+func (t *tokenSource) prime(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.cache != nil {
+		if token, expiresAt, ok := t.cache.load(); ok && time.Until(expiresAt) > t.refreshWindow {
+			t.token = token
+			t.expiresAt = expiresAt
+			return nil
+		}
+	}
+
+	return t.refreshLocked(ctx)
+}
+
+// Token returns a token that is valid for at least refreshWindow, re-authenticating first if
+// it is not (unless refresh has been disabled via disable_token_refresh).
+func (t *tokenSource) Token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.disabled || time.Until(t.expiresAt) > t.refreshWindow {
+		return t.token, nil
+	}
+
+	if err := t.refreshLocked(ctx); err != nil {
+		return "", err
+	}
+	return t.token, nil
+}
+
+func (t *tokenSource) refreshLocked(ctx context.Context) error {
+	token, expiresAt, err := t.refresh(ctx)
+	if err != nil {
+		return err
+	}
+	t.token = token
+	t.expiresAt = expiresAt
+	if t.cache != nil {
+		if err := t.cache.save(token, expiresAt); err != nil {
+			return fmt.Errorf("caching token: %w", err)
+		}
+	}
+	return nil
+}
+
+// startBackgroundRefresh proactively refreshes the token shortly before expiry instead of
+// waiting for the next Token() call, so long-running applies don't pay the refresh latency
+// mid-plan.
+//
+// It deliberately does not inherit the ctx that ConfigureContextFunc receives: that context is
+// scoped to the single ConfigureProvider RPC in terraform-plugin-sdk/v2's grpc transport and is
+// cancelled as soon as configureProvider returns, which would kill this goroutine almost
+// immediately. Instead the goroutine runs against context.Background() and is stopped via
+// Close(), which the provider should call when it is torn down.
+func (t *tokenSource) startBackgroundRefresh() {
+	if t.disabled {
+		return
+	}
+
+	go func() {
+		ctx := context.Background()
+		for {
+			t.mu.Lock()
+			wait := time.Until(t.expiresAt) - t.refreshWindow
+			t.mu.Unlock()
+			if wait < time.Second {
+				wait = time.Second
+			}
+
+			select {
+			case <-t.stopCh:
+				return
+			case <-time.After(wait):
+			}
+
+			if _, err := t.Token(ctx); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+// tokenCache persists the session token between terraform invocations, so back-to-back
+// plan/apply runs reuse it instead of re-authenticating (useful with aws_iam_login and
+// azure_ad_login, where the STS/IMDS round trip adds latency).
+type tokenCache struct {
+	path           string
+	encryptionMode string
+	passphrase     string
+}
+
+type cachedToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func newTokenCache(path, encryptionMode, passphrase string) *tokenCache {
+	if path == "" {
+		return nil
+	}
+	if encryptionMode == "" {
+		encryptionMode = "none"
+	}
+	return &tokenCache{path: path, encryptionMode: encryptionMode, passphrase: passphrase}
+}
+
+func (c *tokenCache) load() (string, time.Time, bool) {
+	lock := flock.New(c.path + ".lock")
+	if err := lock.RLock(); err != nil {
+		return "", time.Time{}, false
+	}
+	defer lock.Unlock()
+
+	raw, err := os.ReadFile(c.path)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+
+	plain, err := c.decrypt(raw)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+
+	var ct cachedToken
+	if err := json.Unmarshal(plain, &ct); err != nil {
+		return "", time.Time{}, false
+	}
+
+	return ct.Token, ct.ExpiresAt, ct.Token != ""
+}
+
+func (c *tokenCache) save(token string, expiresAt time.Time) error {
+	lock := flock.New(c.path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	plain, err := json.Marshal(cachedToken{Token: token, ExpiresAt: expiresAt})
+	if err != nil {
+		return err
+	}
+
+	cipherText, err := c.encrypt(plain)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path, cipherText, 0600)
+}
+
+func (c *tokenCache) encrypt(plain []byte) ([]byte, error) {
+	if c.encryptionMode != "aes-gcm" {
+		return plain, nil
+	}
+
+	key, err := c.key()
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+func (c *tokenCache) decrypt(cipherText []byte) ([]byte, error) {
+	if c.encryptionMode != "aes-gcm" {
+		return cipherText, nil
+	}
+
+	key, err := c.key()
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cipherText) < gcm.NonceSize() {
+		return nil, fmt.Errorf("token cache file is corrupt")
+	}
+	nonce, data := cipherText[:gcm.NonceSize()], cipherText[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, data, nil)
+}
+
+// key derives the AES-256 key for "aes-gcm" mode from passphrase. Unlike a key auto-generated
+// and stored next to the cache file, this means reading the cache file alone doesn't also hand
+// an attacker the key to decrypt it - they additionally need the passphrase, which is never
+// written to disk.
+func (c *tokenCache) key() ([]byte, error) {
+	if c.passphrase == "" {
+		return nil, fmt.Errorf("token_cache.encryption_key (or %s) is required when encryption_mode is aes-gcm", tokenCacheKeyEnv)
+	}
+	sum := sha256.Sum256([]byte(c.passphrase))
+	return sum[:], nil
+}
@@ -0,0 +1,165 @@
+package akeyless
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTokenCacheEncryptDecryptRoundTrip(t *testing.T) {
+	tests := []struct {
+		name           string
+		encryptionMode string
+		passphrase     string
+	}{
+		{name: "none", encryptionMode: "none"},
+		{name: "aes-gcm", encryptionMode: "aes-gcm", passphrase: "correct-horse-battery-staple"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &tokenCache{encryptionMode: tt.encryptionMode, passphrase: tt.passphrase}
+
+			plain := []byte(`{"token":"t-123","expires_at":"2024-01-01T00:00:00Z"}`)
+			cipherText, err := c.encrypt(plain)
+			if err != nil {
+				t.Fatalf("encrypt: %v", err)
+			}
+			if tt.encryptionMode == "aes-gcm" && string(cipherText) == string(plain) {
+				t.Fatalf("aes-gcm encrypt returned plaintext unchanged")
+			}
+
+			got, err := c.decrypt(cipherText)
+			if err != nil {
+				t.Fatalf("decrypt: %v", err)
+			}
+			if string(got) != string(plain) {
+				t.Fatalf("round trip mismatch: got %q, want %q", got, plain)
+			}
+		})
+	}
+}
+
+func TestTokenCacheEncryptAesGcmRequiresPassphrase(t *testing.T) {
+	c := &tokenCache{encryptionMode: "aes-gcm"}
+	if _, err := c.encrypt([]byte("plain")); err == nil {
+		t.Fatal("expected an error when encryption_key is missing")
+	}
+}
+
+func TestTokenCacheDecryptRejectsCorruptCipherText(t *testing.T) {
+	c := &tokenCache{encryptionMode: "aes-gcm", passphrase: "p"}
+	if _, err := c.decrypt([]byte("too-short")); err == nil {
+		t.Fatal("expected an error decrypting a truncated cipher text")
+	}
+}
+
+func TestTokenCacheSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.json")
+	c := newTokenCache(path, "aes-gcm", "p@ssphrase")
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	if err := c.save("s3cr3t", expiresAt); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	token, gotExpiresAt, ok := c.load()
+	if !ok {
+		t.Fatal("load reported no cached token")
+	}
+	if token != "s3cr3t" {
+		t.Fatalf("token = %q, want %q", token, "s3cr3t")
+	}
+	if !gotExpiresAt.Equal(expiresAt) {
+		t.Fatalf("expiresAt = %v, want %v", gotExpiresAt, expiresAt)
+	}
+}
+
+func TestTokenCacheLoadMissingFile(t *testing.T) {
+	c := newTokenCache(filepath.Join(t.TempDir(), "does-not-exist.json"), "none", "")
+	if _, _, ok := c.load(); ok {
+		t.Fatal("load should report no cached token when the file doesn't exist")
+	}
+}
+
+func TestNewTokenCacheNoPath(t *testing.T) {
+	if c := newTokenCache("", "aes-gcm", "p"); c != nil {
+		t.Fatalf("newTokenCache with empty path = %v, want nil", c)
+	}
+}
+
+func TestTokenSourceTokenRefreshesWithinWindow(t *testing.T) {
+	var refreshCalls int
+	refresh := func(ctx context.Context) (string, time.Time, error) {
+		refreshCalls++
+		return "fresh-token", time.Now().Add(time.Hour), nil
+	}
+
+	ts := newTokenSource(refresh, 60*time.Second, false, nil)
+	ts.token = "stale-token"
+	ts.expiresAt = time.Now().Add(30 * time.Second) // inside the refresh window
+
+	token, err := ts.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	if token != "fresh-token" {
+		t.Fatalf("token = %q, want %q", token, "fresh-token")
+	}
+	if refreshCalls != 1 {
+		t.Fatalf("refreshCalls = %d, want 1", refreshCalls)
+	}
+}
+
+func TestTokenSourceTokenSkipsRefreshOutsideWindow(t *testing.T) {
+	var refreshCalls int
+	refresh := func(ctx context.Context) (string, time.Time, error) {
+		refreshCalls++
+		return "fresh-token", time.Now().Add(time.Hour), nil
+	}
+
+	ts := newTokenSource(refresh, 60*time.Second, false, nil)
+	ts.token = "still-good-token"
+	ts.expiresAt = time.Now().Add(time.Hour) // well outside the refresh window
+
+	token, err := ts.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	if token != "still-good-token" {
+		t.Fatalf("token = %q, want %q", token, "still-good-token")
+	}
+	if refreshCalls != 0 {
+		t.Fatalf("refreshCalls = %d, want 0", refreshCalls)
+	}
+}
+
+func TestTokenSourceTokenSkipsRefreshWhenDisabled(t *testing.T) {
+	var refreshCalls int
+	refresh := func(ctx context.Context) (string, time.Time, error) {
+		refreshCalls++
+		return "fresh-token", time.Now().Add(time.Hour), nil
+	}
+
+	ts := newTokenSource(refresh, 60*time.Second, true, nil)
+	ts.token = "stale-but-refresh-disabled"
+	ts.expiresAt = time.Now().Add(-time.Hour) // already expired
+
+	token, err := ts.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	if token != "stale-but-refresh-disabled" {
+		t.Fatalf("token = %q, want the unrefreshed token", token)
+	}
+	if refreshCalls != 0 {
+		t.Fatalf("refreshCalls = %d, want 0", refreshCalls)
+	}
+}
+
+func TestTokenSourceCloseIsIdempotent(t *testing.T) {
+	ts := newTokenSource(nil, 0, true, nil)
+	ts.Close()
+	ts.Close() // must not panic on a second close
+}
@@ -0,0 +1,309 @@
+// Package akeyless implements an HTTP server that stores Terraform state as a versioned
+// Akeyless static secret.
+//
+// Terraform has no plugin mechanism for third-party state backends - unlike providers, the
+// backends bundled with Terraform (s3, gcs, ...) are compiled into the terraform core binary,
+// so a separate Go module cannot register a `backend "akeyless" {}` block. Instead, this
+// package speaks the protocol of Terraform's built-in "http" backend
+// (https://developer.hashicorp.com/terraform/language/settings/backends/http): run Server as
+// a small sidecar that holds the real Akeyless credentials, and point the "http" backend at it:
+//
+// Since this Server holds real Akeyless credentials, ServeHTTP requires every request to
+// carry bearer_token as the password of an HTTP Basic Authorization header - the username is
+// ignored and can be anything.
+//
+//	terraform {
+//	  backend "http" {
+//	    address        = "http://127.0.0.1:8080/state"
+//	    lock_address   = "http://127.0.0.1:8080/state"
+//	    lock_method    = "LOCK"
+//	    unlock_address = "http://127.0.0.1:8080/state"
+//	    unlock_method  = "UNLOCK"
+//	    username       = "terraform"
+//	    password       = "<bearer_token>"
+//	  }
+//	}
+//
+// Named workspaces (`terraform workspace new/select`) have no first-class support in
+// Terraform's "http" backend protocol either - it never sends a workspace name to the remote
+// server. To still isolate state per workspace, a Server infers the workspace from the last
+// path segment of the request URL: point each workspace's generated backend config (e.g. via
+// `terraform init -backend-config=address=...` from a wrapper script keyed on
+// `terraform workspace show`, since backend blocks can't reference terraform.workspace
+// directly) at "<base address>/<workspace>" instead of the bare address, and Server appends
+// that workspace as a suffix to secret_path and lock_path - mirroring how the local backend
+// isolates workspaces under terraform.tfstate.d/<workspace>/. Requests with no extra path
+// segment, or whose last segment is "default", use secret_path/lock_path unchanged. The
+// Server does not know the base address: any request path of two or more segments is read as
+// "<base address>/<workspace>", so the bare address must be a single path segment (such as
+// "/state" above). Under a longer bare address such as "/tf/state", a request to it is taken
+// to belong to workspace "state".
+package akeyless
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/akeylesslabs/akeyless-go/v2"
+	akprovider "github.com/akeylesslabs/terraform-provider-akeyless/akeyless"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const defaultApiGwAddress = "https://api.akeyless.io"
+
+// bearerTokenEnv is the environment variable bearer_token falls back to.
+const bearerTokenEnv = "AKEYLESS_BACKEND_BEARER_TOKEN"
+
+// Server answers Terraform's "http" backend protocol for a single state, backed by an
+// Akeyless static secret. Named workspaces are inferred from the request path rather than
+// sent explicitly by Terraform - see the package doc.
+type Server struct {
+	client            *akeyless.V2ApiService
+	token             string
+	secretPath        string
+	encryptionKeyName string
+	lockPath          string
+	bearerToken       string
+}
+
+// Config holds the settings needed to build a Server.
+type Config struct {
+	ApiGwAddress      string
+	SecretPath        string
+	EncryptionKeyName string
+	LockPath          string
+	BearerToken       string
+}
+
+// ConfigSchema accepts the same four login blocks the provider understands, plus the settings
+// that are specific to where/how state is stored, for callers that build a Config from HCL via
+// *schema.ResourceData the same way the provider does.
+func ConfigSchema() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"api_gateway_address": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Default:     defaultApiGwAddress,
+			Description: "Origin URL of the API Gateway server. This is a URL with a scheme, a hostname and a port.",
+		},
+		"secret_path": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Name of the Akeyless static secret item the state is written to.",
+		},
+		"encryption_key_name": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Name of an Akeyless AES/KMS key used to wrap the state before it is stored.",
+		},
+		"lock_path": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Name of a sibling static secret whose presence/absence implements the state lock.",
+		},
+		"bearer_token": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Sensitive:   true,
+			Description: "Shared secret Terraform's http backend must present as the password of an HTTP Basic Authorization header (see backend_config's username/password). Required because Server holds real Akeyless credentials and is typically bound to a local or otherwise reachable port.",
+			DefaultFunc: schema.EnvDefaultFunc(bearerTokenEnv, nil),
+		},
+	}
+	for name, sch := range akprovider.LoginSchemas() {
+		s[name] = sch
+	}
+	return s
+}
+
+// NewServer authenticates against apiGwAddress using d (populated against ConfigSchema) and
+// returns a Server ready to be mounted as an http.Handler.
+func NewServer(d *schema.ResourceData) (*Server, error) {
+	apiGwAddress := d.Get("api_gateway_address").(string)
+
+	client, token, err := akprovider.Authenticate(d, apiGwAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		client:            client,
+		token:             token,
+		secretPath:        d.Get("secret_path").(string),
+		encryptionKeyName: d.Get("encryption_key_name").(string),
+		lockPath:          d.Get("lock_path").(string),
+		bearerToken:       d.Get("bearer_token").(string),
+	}, nil
+}
+
+// NewServerFromEnv builds a Server the same way NewServer does, but authenticates with
+// akprovider.AuthenticateWithEnv instead of a *schema.ResourceData. It is meant for the
+// standalone server binary (cmd/akeyless-state-server), which has no Terraform config to
+// read login blocks from and so only supports the AKEYLESS_ACCESS_ID/AKEYLESS_ACCESS_KEY
+// environment-variable login.
+func NewServerFromEnv(apiGwAddress, secretPath, encryptionKeyName, lockPath, bearerToken string) (*Server, error) {
+	client, token, err := akprovider.AuthenticateWithEnv(apiGwAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		client:            client,
+		token:             token,
+		secretPath:        secretPath,
+		encryptionKeyName: encryptionKeyName,
+		lockPath:          lockPath,
+		bearerToken:       bearerToken,
+	}, nil
+}
+
+func (s *Server) remoteClient(workspace string) *RemoteClient {
+	return &RemoteClient{
+		akeylessClient:    s.client,
+		token:             s.token,
+		secretPath:        withWorkspace(s.secretPath, workspace),
+		encryptionKeyName: s.encryptionKeyName,
+		lockPath:          withWorkspace(s.lockPath, workspace),
+	}
+}
+
+// workspaceFromPath returns the workspace a request belongs to, inferred from the last
+// segment of a request path of two or more segments - see the package doc. A path of fewer
+// segments has no workspace. "" and "default" both mean the default workspace, i.e. no suffix.
+func workspaceFromPath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) < 2 {
+		return ""
+	}
+	if workspace := segments[len(segments)-1]; workspace != "default" {
+		return workspace
+	}
+	return ""
+}
+
+// withWorkspace appends workspace to path as a suffix, unless workspace is the default
+// workspace or path is empty (lock_path is optional and "" means locking is disabled).
+func withWorkspace(path, workspace string) string {
+	if path == "" || workspace == "" {
+		return path
+	}
+	return path + "/" + workspace
+}
+
+// ServeHTTP implements the "http" backend protocol: GET/POST/DELETE against address, and the
+// custom LOCK/UNLOCK verbs against lock_address/unlock_address. All of them may be routed to
+// the same URL, as in the example in the package doc. The workspace, if any, is inferred from
+// the request path - see the package doc.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="akeyless-state-backend"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	client := s.remoteClient(workspaceFromPath(r.URL.Path))
+
+	switch r.Method {
+	case http.MethodGet:
+		s.handleGet(w, client)
+	case http.MethodPost:
+		s.handlePost(w, r, client)
+	case http.MethodDelete:
+		s.handleDelete(w, client)
+	case "LOCK":
+		s.handleLock(w, r, client)
+	case "UNLOCK":
+		s.handleUnlock(w, r, client)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// authorized reports whether r carries bearer_token as the password of an HTTP Basic
+// Authorization header - the "username"/"password" fields of Terraform's "http" backend are
+// sent this way, and are the only credential mechanism that backend supports. This Server
+// holds real Akeyless credentials, so without this check anyone able to reach its port could
+// read or overwrite state.
+func (s *Server) authorized(r *http.Request) bool {
+	_, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(password), []byte(s.bearerToken)) == 1
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, client *RemoteClient) {
+	payload, err := client.Get()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if payload == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-MD5", base64.StdEncoding.EncodeToString(payload.MD5))
+	w.Write(payload.Data)
+}
+
+func (s *Server) handlePost(w http.ResponseWriter, r *http.Request, client *RemoteClient) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := client.Put(data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, client *RemoteClient) {
+	if err := client.Delete(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleLock(w http.ResponseWriter, r *http.Request, client *RemoteClient) {
+	var info LockInfo
+	if err := json.NewDecoder(r.Body).Decode(&info); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := client.Lock(&info); err != nil {
+		var lockErr *LockError
+		if errors.As(err, &lockErr) && lockErr.Info != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusLocked)
+			json.NewEncoder(w).Encode(lockErr.Info)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleUnlock(w http.ResponseWriter, r *http.Request, client *RemoteClient) {
+	var info LockInfo
+	if err := json.NewDecoder(r.Body).Decode(&info); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := client.Unlock(info.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
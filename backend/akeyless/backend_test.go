@@ -0,0 +1,92 @@
+package akeyless
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWorkspaceFromPath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "bare address", path: "/state", want: ""},
+		{name: "trailing slash", path: "/state/", want: ""},
+		{name: "root", path: "/", want: ""},
+		{name: "default workspace is the default", path: "/state/default", want: ""},
+		{name: "named workspace", path: "/state/prod", want: "prod"},
+		{name: "nested prefix", path: "/tf/state/prod", want: "prod"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := workspaceFromPath(tt.path); got != tt.want {
+				t.Fatalf("workspaceFromPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithWorkspace(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		workspace string
+		want      string
+	}{
+		{name: "default workspace leaves path unchanged", path: "tf-state", workspace: "", want: "tf-state"},
+		{name: "named workspace is appended", path: "tf-state", workspace: "prod", want: "tf-state/prod"},
+		{name: "empty path stays empty (lock_path disabled)", path: "", workspace: "prod", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withWorkspace(tt.path, tt.workspace); got != tt.want {
+				t.Fatalf("withWorkspace(%q, %q) = %q, want %q", tt.path, tt.workspace, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerAuthorized(t *testing.T) {
+	s := &Server{bearerToken: "s3cr3t"}
+
+	tests := []struct {
+		name     string
+		setup    func(r *http.Request)
+		wantAuth bool
+	}{
+		{
+			name:     "correct bearer token as basic auth password",
+			setup:    func(r *http.Request) { r.SetBasicAuth("terraform", "s3cr3t") },
+			wantAuth: true,
+		},
+		{
+			name:     "username is ignored",
+			setup:    func(r *http.Request) { r.SetBasicAuth("anyone", "s3cr3t") },
+			wantAuth: true,
+		},
+		{
+			name:     "wrong password",
+			setup:    func(r *http.Request) { r.SetBasicAuth("terraform", "wrong") },
+			wantAuth: false,
+		},
+		{
+			name:     "no authorization header",
+			setup:    func(r *http.Request) {},
+			wantAuth: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/state", nil)
+			tt.setup(r)
+			if got := s.authorized(r); got != tt.wantAuth {
+				t.Fatalf("authorized() = %v, want %v", got, tt.wantAuth)
+			}
+		})
+	}
+}
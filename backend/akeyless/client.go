@@ -0,0 +1,222 @@
+package akeyless
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/akeylesslabs/akeyless-go/v2"
+)
+
+// Payload is a state blob read back from Akeyless, together with the MD5 sum the server uses
+// to answer Content-MD5 checks in the http backend protocol.
+type Payload struct {
+	Data []byte
+	MD5  []byte
+}
+
+// LockInfo mirrors the JSON body the Terraform "http" backend protocol sends with LOCK
+// requests and expects back from a conflicting lock.
+type LockInfo struct {
+	ID        string `json:"ID"`
+	Operation string `json:"Operation"`
+	Who       string `json:"Who"`
+	Info      string `json:"Info"`
+}
+
+// LockError is returned by RemoteClient.Lock when it cannot acquire the lock. Info is set only
+// when the state is already held by someone else, and then describes that holder.
+type LockError struct {
+	Info *LockInfo
+	Err  error
+}
+
+func (e *LockError) Error() string {
+	return e.Err.Error()
+}
+
+// RemoteClient reads and writes Terraform state as an Akeyless static secret, and uses a
+// sibling static secret as a lock. It has no dependency on Terraform itself; Server calls it
+// to answer the http backend protocol over the wire.
+type RemoteClient struct {
+	akeylessClient    *akeyless.V2ApiService
+	token             string
+	secretPath        string
+	encryptionKeyName string
+	lockPath          string
+}
+
+// Get fetches the current state.
+func (c *RemoteClient) Get() (*Payload, error) {
+	var apiErr akeyless.GenericOpenAPIError
+	ctx := context.Background()
+
+	body := akeyless.GetSecretValue{
+		Names: []string{c.secretPath},
+		Token: &c.token,
+	}
+
+	out, res, err := c.akeylessClient.GetSecretValue(ctx).Body(body).Execute()
+	if err != nil {
+		if errors.As(err, &apiErr) && res != nil && res.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("can't get state: %v", err)
+	}
+
+	value, ok := out[c.secretPath]
+	if !ok {
+		return nil, nil
+	}
+	data := []byte(fmt.Sprintf("%v", value))
+
+	sum := md5.Sum(data)
+	return &Payload{
+		Data: data,
+		MD5:  sum[:],
+	}, nil
+}
+
+// Put writes state.
+func (c *RemoteClient) Put(data []byte) error {
+	var apiErr akeyless.GenericOpenAPIError
+	ctx := context.Background()
+
+	body := akeyless.UpdateSecretVal{
+		Name:  c.secretPath,
+		Value: string(data),
+		Token: &c.token,
+	}
+	if c.encryptionKeyName != "" {
+		body.KeyName = akeyless.PtrString(c.encryptionKeyName)
+	}
+
+	_, _, err := c.akeylessClient.UpdateSecretVal(ctx).Body(body).Execute()
+	if err != nil {
+		if errors.As(err, &apiErr) {
+			return fmt.Errorf("can't write state: %v", string(apiErr.Body()))
+		}
+		return fmt.Errorf("can't write state: %v", err)
+	}
+
+	return nil
+}
+
+// Delete removes the state secret.
+func (c *RemoteClient) Delete() error {
+	ctx := context.Background()
+	_, _, err := c.akeylessClient.DeleteItem(ctx).Body(akeyless.DeleteItem{
+		Name:  c.secretPath,
+		Token: &c.token,
+	}).Execute()
+	return err
+}
+
+// Lock creates lockPath as a new secret (with encryption_key_name as its ProtectionKey, when
+// one is configured), so a concurrent Lock call conflicts instead
+// of silently overwriting an existing lock. It checks currentLockInfo itself before calling
+// CreateSecret rather than relying solely on CreateSecret to reject an existing name - the
+// Akeyless API's create-vs-upsert behavior for a name collision is not confirmed in this tree,
+// and plenty of secret-manager "create" calls silently upsert. That check-then-create is still
+// a TOCTOU race between two concurrent Lock calls, not a true compare-and-set; closing it fully
+// needs either a confirmed "fail if exists" CreateSecret, or a conditional-write primitive from
+// the API. A conflict, whether caught by the pre-check or surfaced by CreateSecret itself, is
+// returned as a *LockError so Server can answer 423 Locked with who's holding it.
+func (c *RemoteClient) Lock(info *LockInfo) (string, error) {
+	if c.lockPath == "" {
+		return "", nil
+	}
+
+	if existing, err := c.currentLockInfo(); err == nil && existing != nil {
+		return "", &LockError{Info: existing, Err: fmt.Errorf("state is already locked by %s", existing.Who)}
+	}
+
+	payload, err := json.Marshal(info)
+	if err != nil {
+		return "", err
+	}
+
+	var apiErr akeyless.GenericOpenAPIError
+	ctx := context.Background()
+
+	body := akeyless.CreateSecret{
+		Name:  c.lockPath,
+		Value: string(payload),
+		Token: &c.token,
+	}
+	if c.encryptionKeyName != "" {
+		body.ProtectionKey = akeyless.PtrString(c.encryptionKeyName)
+	}
+
+	_, _, err = c.akeylessClient.CreateSecret(ctx).Body(body).Execute()
+	if err != nil {
+		existing, getErr := c.currentLockInfo()
+		if getErr == nil && existing != nil {
+			return "", &LockError{Info: existing, Err: fmt.Errorf("state is already locked by %s", existing.Who)}
+		}
+		if errors.As(err, &apiErr) {
+			return "", &LockError{Err: fmt.Errorf("can't acquire lock: %v", string(apiErr.Body()))}
+		}
+		return "", &LockError{Err: fmt.Errorf("can't acquire lock: %v", err)}
+	}
+
+	return info.ID, nil
+}
+
+// Unlock deletes lockPath, but only if the lock currently stored there matches id.
+func (c *RemoteClient) Unlock(id string) error {
+	if c.lockPath == "" {
+		return nil
+	}
+
+	existing, err := c.currentLockInfo()
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return nil
+	}
+	if existing.ID != id {
+		return fmt.Errorf("lock id mismatch: held by %s, asked to unlock %s", existing.ID, id)
+	}
+
+	ctx := context.Background()
+	_, _, err = c.akeylessClient.DeleteItem(ctx).Body(akeyless.DeleteItem{
+		Name:  c.lockPath,
+		Token: &c.token,
+	}).Execute()
+	return err
+}
+
+func (c *RemoteClient) currentLockInfo() (*LockInfo, error) {
+	var apiErr akeyless.GenericOpenAPIError
+	ctx := context.Background()
+
+	body := akeyless.GetSecretValue{
+		Names: []string{c.lockPath},
+		Token: &c.token,
+	}
+
+	out, res, err := c.akeylessClient.GetSecretValue(ctx).Body(body).Execute()
+	if err != nil {
+		if errors.As(err, &apiErr) && res != nil && res.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	value, ok := out[c.lockPath]
+	if !ok {
+		return nil, nil
+	}
+
+	var lv LockInfo
+	if err := json.Unmarshal([]byte(fmt.Sprintf("%v", value)), &lv); err != nil {
+		return nil, err
+	}
+
+	return &lv, nil
+}
@@ -0,0 +1,53 @@
+// Command akeyless-state-server runs backend/akeyless.Server as a standalone HTTP sidecar,
+// so it can be pointed at from Terraform's "http" backend block (see the package doc on
+// github.com/akeylesslabs/terraform-provider-akeyless/backend/akeyless for the backend
+// configuration). Configuration is read entirely from the environment, since this binary has
+// no Terraform config of its own to read a login block from; it authenticates with
+// AKEYLESS_ACCESS_ID/AKEYLESS_ACCESS_KEY.
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	backend "github.com/akeylesslabs/terraform-provider-akeyless/backend/akeyless"
+)
+
+func main() {
+	apiGwAddress := os.Getenv("AKEYLESS_API_GATEWAY_ADDRESS")
+	if apiGwAddress == "" {
+		apiGwAddress = "https://api.akeyless.io"
+	}
+
+	secretPath := os.Getenv("AKEYLESS_STATE_SECRET_PATH")
+	if secretPath == "" {
+		log.Fatal("AKEYLESS_STATE_SECRET_PATH is required")
+	}
+
+	bearerToken := os.Getenv("AKEYLESS_BACKEND_BEARER_TOKEN")
+	if bearerToken == "" {
+		log.Fatal("AKEYLESS_BACKEND_BEARER_TOKEN is required")
+	}
+
+	listenAddr := os.Getenv("AKEYLESS_BACKEND_LISTEN_ADDR")
+	if listenAddr == "" {
+		listenAddr = "127.0.0.1:8080"
+	}
+
+	server, err := backend.NewServerFromEnv(
+		apiGwAddress,
+		secretPath,
+		os.Getenv("AKEYLESS_STATE_ENCRYPTION_KEY_NAME"),
+		os.Getenv("AKEYLESS_STATE_LOCK_PATH"),
+		bearerToken,
+	)
+	if err != nil {
+		log.Fatalf("can't start akeyless-state-server: %v", err)
+	}
+
+	log.Printf("akeyless-state-server listening on %s", listenAddr)
+	if err := http.ListenAndServe(listenAddr, server); err != nil {
+		log.Fatal(err)
+	}
+}